@@ -0,0 +1,287 @@
+package orderbook
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/google/btree"
+	"github.com/shopspring/decimal"
+)
+
+// ErrNoCross is returned by MatchAuction when the book cannot be crossed,
+// either because one side is empty or because the best bid is below the
+// best ask and no clearing price exists.
+var ErrNoCross = errors.New("orderbook: no cross")
+
+// auctionLevel is one aggregated price level used while computing the
+// uniform clearing price for MatchAuction.
+type auctionLevel struct {
+	price  decimal.Decimal
+	volume decimal.Decimal
+}
+
+// Enqueue rests order in the book without attempting to match it against
+// the opposite side, bypassing the TIF and post-only checks that Match
+// enforces during continuous trading. It exists for callers running a
+// uniform-price call auction: orders accumulate via Enqueue during the
+// call phase without crossing, then MatchAuction clears the whole book
+// at once at the computed clearing price. It returns order for chaining.
+func (e *Engine) Enqueue(order *Order) *Order {
+	e.bump()
+	e.openLimit(order)
+
+	return order
+}
+
+// MatchAuction performs a uniform-price batch (call) auction against the
+// resting book: it picks a single clearing price, the one that maximizes
+// matched volume or, when several prices tie, the one clearingPrice's
+// tie-break yields (which can match less than the maximum), and crosses
+// both sides against it in price-time priority, producing
+// Trade records priced at the clearing price rather than the resting
+// limit prices. Market orders in the book are treated as infinitely
+// aggressive (price = +inf on the buy side, 0 on the sell side) when
+// ranking, but still trade at the clearing price. It returns ErrNoCross
+// if either side is empty or the book does not cross.
+func (e *Engine) MatchAuction() ([]Trade, error) {
+	clearing, matched, ok := e.clearingPrice()
+	if !ok || matched.IsZero() {
+		return nil, ErrNoCross
+	}
+
+	e.bump()
+
+	buyOrders := eligibleAuctionOrders(e.buys, SideBuy, clearing)
+	sellOrders := eligibleAuctionOrders(e.sells, SideSell, clearing)
+
+	trades := make([]Trade, 0)
+
+	i, j := 0, 0
+	for i < len(buyOrders) && j < len(sellOrders) {
+		buy, sell := buyOrders[i], sellOrders[j]
+
+		amount := buy.Volume
+		if sell.Volume.LessThan(amount) {
+			amount = sell.Volume
+		}
+
+		trade := e.executeAt(buy, sell, amount, clearing)
+		trades = append(trades, *trade)
+
+		if buy.Volume.IsZero() {
+			e.buys.Delete(buy)
+			if buy.Reserve.IsPositive() {
+				e.replenishIceberg(buy)
+				e.buys.ReplaceOrInsert(buy)
+			}
+			i++
+		}
+
+		if sell.Volume.IsZero() {
+			e.sells.Delete(sell)
+			if sell.Reserve.IsPositive() {
+				e.replenishIceberg(sell)
+				e.sells.ReplaceOrInsert(sell)
+			}
+			j++
+		}
+	}
+
+	return e.processTriggeredStops(trades), nil
+}
+
+// clearingPrice finds the price P that maximizes min(cumBuy(P), cumSell(P)),
+// the matched auction volume, where cumBuy(P) is the cumulative buy volume
+// at prices >= P and cumSell(P) the cumulative sell volume at prices <= P.
+// It reports false if neither side can produce any match. If several
+// prices tie for the best matched volume, the clearing price is the
+// midpoint of the highest buy price and lowest sell price among the tied
+// candidates; if the tie isn't bracketed by both a buy and a sell price
+// (e.g. every tied price came from the same side), it falls back to the
+// last trade price if one is known, and reports false otherwise.
+func (e *Engine) clearingPrice() (decimal.Decimal, decimal.Decimal, bool) {
+	buyLevels, buyMarket := auctionLevels(e.buys)
+	sellLevels, sellMarket := auctionLevels(e.sells)
+
+	if len(buyLevels) == 0 && buyMarket.IsZero() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	if len(sellLevels) == 0 && sellMarket.IsZero() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	cumBuyAt := func(p decimal.Decimal) decimal.Decimal {
+		cum := buyMarket
+		for _, lvl := range buyLevels {
+			if lvl.price.GreaterThanOrEqual(p) {
+				cum = cum.Add(lvl.volume)
+			}
+		}
+		return cum
+	}
+
+	cumSellAt := func(p decimal.Decimal) decimal.Decimal {
+		cum := sellMarket
+		for _, lvl := range sellLevels {
+			if lvl.price.LessThanOrEqual(p) {
+				cum = cum.Add(lvl.volume)
+			}
+		}
+		return cum
+	}
+
+	candidates := make([]decimal.Decimal, 0, len(buyLevels)+len(sellLevels))
+	for _, lvl := range buyLevels {
+		candidates = append(candidates, lvl.price)
+	}
+	for _, lvl := range sellLevels {
+		candidates = append(candidates, lvl.price)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].LessThan(candidates[j]) })
+
+	var best, tieLow, tieHigh decimal.Decimal
+
+	haveTie := false
+
+	for _, p := range candidates {
+		matched := decimal.Min(cumBuyAt(p), cumSellAt(p))
+
+		switch {
+		case matched.GreaterThan(best):
+			best = matched
+			tieLow, tieHigh = p, p
+			haveTie = true
+		case haveTie && matched.Equal(best):
+			if p.LessThan(tieLow) {
+				tieLow = p
+			}
+
+			if p.GreaterThan(tieHigh) {
+				tieHigh = p
+			}
+		}
+	}
+
+	if best.IsZero() {
+		return decimal.Zero, decimal.Zero, false
+	}
+
+	if tieLow.Equal(tieHigh) {
+		return tieLow, best, true
+	}
+
+	var tieBuyHigh, tieSellLow decimal.Decimal
+
+	haveTieBuy, haveTieSell := false, false
+
+	for _, lvl := range buyLevels {
+		if decimal.Min(cumBuyAt(lvl.price), cumSellAt(lvl.price)).Equal(best) {
+			if !haveTieBuy || lvl.price.GreaterThan(tieBuyHigh) {
+				tieBuyHigh = lvl.price
+			}
+
+			haveTieBuy = true
+		}
+	}
+
+	for _, lvl := range sellLevels {
+		if decimal.Min(cumBuyAt(lvl.price), cumSellAt(lvl.price)).Equal(best) {
+			if !haveTieSell || lvl.price.LessThan(tieSellLow) {
+				tieSellLow = lvl.price
+			}
+
+			haveTieSell = true
+		}
+	}
+
+	if haveTieBuy && haveTieSell {
+		return tieBuyHigh.Add(tieSellLow).Div(decimal.NewFromInt(2)), best, true
+	}
+
+	if !e.lastTradePrice.IsZero() {
+		return e.lastTradePrice, best, true
+	}
+
+	return decimal.Zero, decimal.Zero, false
+}
+
+// auctionLevels aggregates a side of the book into price levels ordered as
+// the underlying tree iterates them, plus the total volume resting as
+// market orders on that side (which has no price level of its own).
+func auctionLevels(tree *btree.BTree) ([]auctionLevel, decimal.Decimal) {
+	levels := make([]auctionLevel, 0)
+	market := decimal.Zero
+
+	var current *auctionLevel
+
+	tree.Ascend(func(i btree.Item) bool {
+		order := i.(*Order)
+
+		if order.Kind == KindMarket {
+			market = market.Add(order.Volume)
+			return true
+		}
+
+		if current != nil && current.price.Equal(order.Price) {
+			current.volume = current.volume.Add(order.Volume)
+			return true
+		}
+
+		levels = append(levels, auctionLevel{price: order.Price, volume: order.Volume})
+		current = &levels[len(levels)-1]
+
+		return true
+	})
+
+	return levels, market
+}
+
+// eligibleAuctionOrders returns the orders on one side of the book that are
+// willing to trade at the given clearing price, in the priority order the
+// tree already maintains (price, then time), except that market buys,
+// which the tree sorts last by their zero Price, are ranked first.
+func eligibleAuctionOrders(tree *btree.BTree, side Side, clearing decimal.Decimal) []*Order {
+	orders := make([]*Order, 0, tree.Len())
+	markets := make([]*Order, 0)
+
+	tree.Ascend(func(i btree.Item) bool {
+		order := i.(*Order)
+
+		switch side {
+		case SideBuy:
+			if order.Kind == KindMarket {
+				markets = append(markets, order)
+			} else if order.Price.GreaterThanOrEqual(clearing) {
+				orders = append(orders, order)
+			}
+		case SideSell:
+			if order.Kind == KindMarket || order.Price.LessThanOrEqual(clearing) {
+				orders = append(orders, order)
+			}
+		}
+
+		return true
+	})
+
+	return append(markets, orders...)
+}
+
+// executeAt crosses a buy and a sell order at a fixed price, as used by
+// the auction matcher where trades always print at the clearing price
+// rather than either resting order's limit price. It runs the same
+// accounting and notifications as continuous matching (fees, taker/maker
+// population, trade/fill events, and the tape price stops watch), via
+// applyFill. Whichever order arrived later, by ID, is treated as the
+// taker; in a call auction neither side is genuinely aggressing, but this
+// keeps fee attribution consistent with Order.ID being assigned in
+// arrival order everywhere else in the engine.
+func (e *Engine) executeAt(buy, sell *Order, amount, price decimal.Decimal) *Trade {
+	taker, maker := buy, sell
+	if sell.ID > buy.ID {
+		taker, maker = sell, buy
+	}
+
+	return e.applyFill(taker, maker, amount, price)
+}
@@ -0,0 +1,148 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func mustMatch(t *testing.T, book *orderbook.Engine, order *orderbook.Order) {
+	t.Helper()
+
+	_, _, err := book.Match(order)
+	assert.NoError(t, err)
+}
+
+// mustEnqueue rests order in the book for the call-auction accumulation
+// phase, without letting it cross continuously like mustMatch would.
+func mustEnqueue(t *testing.T, book *orderbook.Engine, order *orderbook.Order) {
+	t.Helper()
+
+	book.Enqueue(order)
+}
+
+func TestEngine_MatchAuction_NoCross(t *testing.T) {
+	book := orderbook.New()
+
+	mustEnqueue(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideSell))
+	mustEnqueue(t, book, limitOrder(2, "1.0", "5000.0", orderbook.SideBuy))
+
+	trades, err := book.MatchAuction()
+	assert.ErrorIs(t, err, orderbook.ErrNoCross)
+	assert.Empty(t, trades)
+}
+
+func TestEngine_MatchAuction_EmptySide(t *testing.T) {
+	book := orderbook.New()
+
+	mustEnqueue(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideBuy))
+
+	trades, err := book.MatchAuction()
+	assert.ErrorIs(t, err, orderbook.ErrNoCross)
+	assert.Empty(t, trades)
+}
+
+func TestEngine_MatchAuction_ClearsAtMidpointOfTie(t *testing.T) {
+	book := orderbook.New()
+
+	mustEnqueue(t, book, limitOrder(1, "1.0", "5100.0", orderbook.SideBuy))
+	mustEnqueue(t, book, limitOrder(2, "1.0", "5000.0", orderbook.SideSell))
+
+	trades, err := book.MatchAuction()
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+
+	expected, _ := decimal.NewFromString("5050")
+	assert.True(t, expected.Equal(trades[0].Price), "expected %s, got %s", expected, trades[0].Price)
+	assert.Equal(t, uint64(1), trades[0].Buy.ID)
+	assert.Equal(t, uint64(2), trades[0].Sell.ID)
+}
+
+func TestEngine_MatchAuction_MaximizesVolume(t *testing.T) {
+	book := orderbook.New()
+
+	mustEnqueue(t, book, limitOrder(1, "1.0", "5200.0", orderbook.SideBuy))
+	mustEnqueue(t, book, limitOrder(2, "1.0", "5100.0", orderbook.SideBuy))
+	mustEnqueue(t, book, limitOrder(3, "1.0", "5000.0", orderbook.SideSell))
+	mustEnqueue(t, book, limitOrder(4, "1.0", "5100.0", orderbook.SideSell))
+
+	trades, err := book.MatchAuction()
+	assert.NoError(t, err)
+	assert.Len(t, trades, 2)
+
+	var matched decimal.Decimal
+	for _, trd := range trades {
+		matched = matched.Add(trd.Amount)
+		assert.True(t, trd.Price.Equal(trades[0].Price))
+	}
+
+	expected, _ := decimal.NewFromString("2.0")
+	assert.True(t, expected.Equal(matched))
+}
+
+func TestEngine_MatchAuction_DoesNotCrossDuringAccumulation(t *testing.T) {
+	book := orderbook.New()
+
+	// A resting sell at 5000 and an incoming buy at 5100 would cross
+	// immediately under continuous matching; Enqueue must park both
+	// instead, leaving the cross for MatchAuction to resolve in one shot.
+	mustEnqueue(t, book, limitOrder(1, "1.0", "5000.0", orderbook.SideSell))
+	mustEnqueue(t, book, limitOrder(2, "1.0", "5100.0", orderbook.SideBuy))
+
+	assert.Contains(t, book.String(), "5100")
+	assert.Contains(t, book.String(), "5000")
+}
+
+func TestEngine_MatchAuction_TieFallsBackToLastTradePrice(t *testing.T) {
+	book := orderbook.New()
+
+	// Establish a known last trade price via an ordinary continuous
+	// match, which also clears this small book back out before the
+	// auction book below is built.
+	mustMatch(t, book, limitOrder(1, "0.1", "4950.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(2, "0.1", "4950.0", orderbook.SideBuy))
+
+	// The buy side is pure market volume, with no price levels of its
+	// own, so the matched volume plateaus at the market volume (3.0)
+	// across more than one sell price. The tie this produces has
+	// no buy-side candidate to bracket it, so it must fall back to the
+	// last trade price rather than averaging sell prices against each
+	// other.
+	mustEnqueue(t, book, marketOrder(3, "3.0", orderbook.SideBuy))
+	mustEnqueue(t, book, limitOrder(4, "2.0", "4900.0", orderbook.SideSell))
+	mustEnqueue(t, book, limitOrder(5, "2.0", "5000.0", orderbook.SideSell))
+	mustEnqueue(t, book, limitOrder(6, "1.0", "5100.0", orderbook.SideSell))
+
+	trades, err := book.MatchAuction()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, trades)
+
+	expected, _ := decimal.NewFromString("4950")
+	assert.True(t, expected.Equal(trades[0].Price), "expected %s, got %s", expected, trades[0].Price)
+}
+
+func TestEngine_MatchAuction_AppliesFeesAndEvents(t *testing.T) {
+	book := orderbook.New(orderbook.WithFeeModel(orderbook.FlatFeeModel{
+		MakerRate: decimal.RequireFromString("0.001"),
+		TakerRate: decimal.RequireFromString("0.002"),
+	}))
+
+	var fired []orderbook.Trade
+	book.OnTrade(func(trd orderbook.Trade) { fired = append(fired, trd) })
+
+	mustEnqueue(t, book, limitOrder(1, "1.0", "5100.0", orderbook.SideBuy))
+	mustEnqueue(t, book, limitOrder(2, "1.0", "5000.0", orderbook.SideSell))
+
+	trades, err := book.MatchAuction()
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+	assert.Len(t, fired, 1, "OnTrade should fire for auction fills")
+
+	// Order 2 (the sell) arrived later, so it is the taker.
+	assert.Equal(t, orderbook.SideSell, trades[0].Taker)
+	assert.False(t, trades[0].TakerFee.IsZero(), "auction fill should be charged a taker fee")
+	assert.False(t, trades[0].MakerFee.IsZero(), "auction fill should be charged a maker fee")
+}
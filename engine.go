@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/google/btree"
 	"github.com/shopspring/decimal"
@@ -11,27 +12,78 @@ import (
 
 // Engine is core structure for matching engine.
 type Engine struct {
-	buys  *btree.BTree
-	sells *btree.BTree
+	buys     *btree.BTree
+	sells    *btree.BTree
+	feeModel FeeModel
+	clock    func() time.Time
+	sequence uint64
+
+	icebergSeq uint64
+
+	stopsBuy       *btree.BTree
+	stopsSell      *btree.BTree
+	lastTradePrice decimal.Decimal
+
+	onTrade                []func(Trade)
+	onOrderOpen            []func(*Order)
+	onOrderFilled          []func(*Order)
+	onOrderPartiallyFilled []func(*Order, decimal.Decimal)
+	onOrderCancelled       []func(*Order)
+	onOrderRejected        []func(*Order, error)
+}
+
+// Option configures an Engine at construction time.
+type Option func(*Engine)
+
+// WithFeeModel sets the FeeModel used to compute maker/taker fees on every
+// executed trade. Defaults to ZeroFeeModel, which charges nothing.
+func WithFeeModel(model FeeModel) Option {
+	return func(e *Engine) {
+		e.feeModel = model
+	}
 }
 
 // New creates new order-book engine.
-func New() *Engine {
-	return &Engine{
-		buys:  btree.New(32),
-		sells: btree.New(32),
+func New(opts ...Option) *Engine {
+	e := &Engine{
+		buys:      btree.New(32),
+		sells:     btree.New(32),
+		stopsBuy:  btree.New(32),
+		stopsSell: btree.New(32),
+		feeModel:  ZeroFeeModel{},
+		clock:     time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(e)
 	}
+
+	return e
 }
 
-func (e *Engine) matchLimit(model *Order) []Trade {
-	switch model.Side {
+func (e *Engine) matchLimit(order *Order) ([]Trade, *Order) {
+	var opposite *btree.BTree
+
+	switch order.Side {
 	case SideSell:
-		return e.matchLimitSide(model, e.buys)
+		opposite = e.buys
 	case SideBuy:
-		return e.matchLimitSide(model, e.sells)
+		opposite = e.sells
+	default:
+		return nil, nil
+	}
+
+	if order.TIF == TIFPostOnly && e.crosses(order, opposite) {
+		e.fireOrderRejected(order, ErrPostOnlyCross)
+		return nil, order
+	}
+
+	if order.TIF == TIFFOK && !e.canFullyFillLimit(order, opposite) {
+		e.fireOrderRejected(order, ErrFillOrKill)
+		return nil, order
 	}
 
-	return nil
+	return e.matchLimitSide(order, opposite)
 }
 
 func (e *Engine) matchMarket(order *Order) ([]Trade, *Order) {
@@ -55,6 +107,13 @@ func (e *Engine) match(order *Order, side *btree.BTree) []Trade {
 
 		// At this point offer still persists in the order-book.
 		other := side.Min().(*Order)
+
+		if e.expired(other) {
+			side.DeleteMin()
+			e.bump()
+			continue
+		}
+
 		if !ordersMatch(order, other) {
 			break
 		}
@@ -64,6 +123,11 @@ func (e *Engine) match(order *Order, side *btree.BTree) []Trade {
 
 		if other.Volume.IsZero() {
 			side.DeleteMin()
+
+			if other.Reserve.IsPositive() {
+				e.replenishIceberg(other)
+				side.ReplaceOrInsert(other)
+			}
 		}
 	}
 
@@ -71,43 +135,70 @@ func (e *Engine) match(order *Order, side *btree.BTree) []Trade {
 }
 
 func (e *Engine) matchMarketWithTree(side *btree.BTree, order *Order) ([]Trade, *Order) {
-	if ok := estimateMarket(order, side); !ok {
+	if ok := e.estimateMarket(order, side); !ok {
+		e.fireOrderRejected(order, ErrInsufficientLiquidity)
 		return nil, order
 	}
 
 	trades := e.match(order, side)
 
 	if order.Volume.IsPositive() {
+		e.fireOrderRejected(order, ErrInsufficientLiquidity)
 		return trades, order
 	}
 
 	return trades, nil
 }
 
-func (e *Engine) matchLimitSide(order *Order, side *btree.BTree) []Trade {
+func (e *Engine) matchLimitSide(order *Order, side *btree.BTree) ([]Trade, *Order) {
 	trades := e.match(order, side)
 
 	if order.Volume.IsPositive() {
+		if order.TIF == TIFIOC || order.TIF == TIFFOK {
+			reason := ErrImmediateOrCancel
+			if order.TIF == TIFFOK {
+				reason = ErrFillOrKill
+			}
+
+			e.fireOrderRejected(order, reason)
+
+			return trades, order
+		}
+
 		e.openLimit(order)
 	}
 
-	return trades
+	return trades, nil
 }
 
-func estimateMarket(order *Order, side *btree.BTree) bool {
+// estimateMarket reports whether order's locked funds cover sweeping
+// enough resting liquidity on side to fill it completely, without
+// performing any matching. It is match's pre-trade gate for market
+// orders, so it must agree with what match will actually do: expired
+// TIFGTD orders are lazily evicted there, not traded against, and so
+// must not count as available liquidity here either.
+func (e *Engine) estimateMarket(order *Order, side *btree.BTree) bool {
 	var price, volume decimal.Decimal
 
 	side.Ascend(func(i btree.Item) bool {
 		other := i.(*Order)
 
-		if volume.Add(other.Volume).GreaterThanOrEqual(order.Volume) {
+		if e.expired(other) {
+			return true
+		}
+
+		// An iceberg's Reserve is hidden from snapshots but still real
+		// liquidity the engine can sweep through via replenishment.
+		available := other.Volume.Add(other.Reserve)
+
+		if volume.Add(available).GreaterThanOrEqual(order.Volume) {
 			price = price.Add(CalculateLocked(order.Volume.Sub(volume), other.Price, order.Side))
 			volume = volume.Add(order.Volume.Sub(volume))
 			return false
 		}
 
-		volume = volume.Add(other.Volume)
-		price = price.Add(CalculateLocked(other.Volume, other.Price, order.Side))
+		volume = volume.Add(available)
+		price = price.Add(CalculateLocked(available, other.Price, order.Side))
 
 		return true
 	})
@@ -140,9 +231,6 @@ func (e *Engine) execute(order, other *Order) *Trade {
 		amount = other.Volume
 	}
 
-	order.Volume = order.Volume.Sub(amount)
-	other.Volume = other.Volume.Sub(amount)
-
 	price := order.Price
 	if price.IsZero() {
 		price = other.Price
@@ -150,16 +238,42 @@ func (e *Engine) execute(order, other *Order) *Trade {
 		price = other.Price
 	}
 
-	orderFunds := CalculateLocked(amount, price, order.Side)
-	otherFunds := CalculateLocked(amount, price, other.Side)
+	return e.applyFill(order, other, amount, price)
+}
+
+// applyFill performs the accounting and notifications shared by every
+// trade the engine produces, regardless of which matcher found the
+// cross: it debits volume and locked funds, credits proceeds net of
+// fees, records the tape price, and fires OnTrade/OnOrderFilled/
+// OnOrderPartiallyFilled. taker is always the aggressing order; maker
+// the resting one.
+func (e *Engine) applyFill(taker, maker *Order, amount, price decimal.Decimal) *Trade {
+	taker.Volume = taker.Volume.Sub(amount)
+	maker.Volume = maker.Volume.Sub(amount)
+
+	takerFunds := CalculateLocked(amount, price, taker.Side)
+	makerFunds := CalculateLocked(amount, price, maker.Side)
 
-	order.Locked = order.Locked.Sub(orderFunds)
-	other.Locked = other.Locked.Sub(otherFunds)
+	taker.Locked = taker.Locked.Sub(takerFunds)
+	maker.Locked = maker.Locked.Sub(makerFunds)
 
-	order.Received = order.Received.Add(otherFunds)
-	other.Received = other.Received.Add(orderFunds)
+	e.lastTradePrice = price
 
-	return trade(order, other, amount, price)
+	trd := trade(taker, maker, amount, price)
+	trd.Taker = taker.Side
+
+	makerFee, takerFee := e.feeModel.Fee(trd, taker.Side)
+	trd.MakerFee = makerFee
+	trd.TakerFee = takerFee
+
+	taker.Received = taker.Received.Add(makerFunds.Sub(takerFee))
+	maker.Received = maker.Received.Add(takerFunds.Sub(makerFee))
+
+	e.fireTrade(*trd)
+	e.fireFill(taker, amount)
+	e.fireFill(maker, amount)
+
+	return trd
 }
 
 func trade(first, second *Order, amount, price decimal.Decimal) *Trade {
@@ -175,22 +289,32 @@ func trade(first, second *Order, amount, price decimal.Decimal) *Trade {
 }
 
 func (e *Engine) openLimit(order *Order) {
+	splitIceberg(order)
+
 	switch order.Side {
 	case SideBuy:
 		e.buys.ReplaceOrInsert(order)
 	case SideSell:
 		e.sells.ReplaceOrInsert(order)
 	}
+
+	e.fireOrderOpen(order)
 }
 
 // Match matches upcoming order with orders in order-book.
 func (e *Engine) Match(order *Order) ([]Trade, *Order, error) {
+	e.bump()
+
 	switch order.Kind {
 	case KindLimit:
-		return e.matchLimit(order), nil, nil
+		trades, reject := e.matchLimit(order)
+		return e.processTriggeredStops(trades), reject, nil
 	case KindMarket:
 		trades, order := e.matchMarket(order)
-		return trades, order, nil
+		return e.processTriggeredStops(trades), order, nil
+	case KindStop, KindStopLimit:
+		e.parkStop(order)
+		return nil, nil, nil
 	}
 
 	return nil, nil, nil
@@ -211,7 +335,17 @@ func (e *Engine) Cancel(order *Order) *Order {
 		return nil
 	}
 
-	return item.(*Order)
+	cancelled := item.(*Order)
+	e.bump()
+	e.fireOrderCancelled(cancelled)
+
+	return cancelled
+}
+
+// bump advances the sequence number, letting consumers of Snapshot detect
+// that the book changed between reads.
+func (e *Engine) bump() {
+	e.sequence++
 }
 
 // String returns orders from order-book.
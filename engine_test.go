@@ -16,6 +16,8 @@ type testCase struct {
 	Orders  []*orderbook.Order
 	Trades  []orderbook.Trade
 	Rejects []*orderbook.Order
+	// Clock, if set, overrides the engine's clock (used by TIFGTD tests).
+	Clock func() time.Time
 }
 
 // New creates new instance of order model.
@@ -116,7 +118,12 @@ func (tCase *testCase) Reject(id uint64, side orderbook.Side) *testCase {
 func (tCase *testCase) Assert(t *testing.T) {
 	test := assert.New(t)
 
-	book := orderbook.New()
+	var opts []orderbook.Option
+	if tCase.Clock != nil {
+		opts = append(opts, orderbook.WithClock(tCase.Clock))
+	}
+
+	book := orderbook.New(opts...)
 
 	var trades []orderbook.Trade
 	var rejects []*orderbook.Order
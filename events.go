@@ -0,0 +1,153 @@
+package orderbook
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+// ErrInsufficientLiquidity is passed to OnOrderRejected callbacks when a
+// market order cannot be filled within its locked funds.
+var ErrInsufficientLiquidity = errors.New("orderbook: insufficient liquidity")
+
+// EventType identifies the kind of change an Event reports.
+type EventType string
+
+const (
+	// EventTrade reports a trade produced by the engine.
+	EventTrade EventType = "trade"
+	// EventOrderOpen reports an order resting in the book.
+	EventOrderOpen EventType = "order_open"
+	// EventOrderFilled reports an order whose volume reached zero.
+	EventOrderFilled EventType = "order_filled"
+	// EventOrderPartiallyFilled reports an order that traded but still
+	// has volume remaining.
+	EventOrderPartiallyFilled EventType = "order_partially_filled"
+	// EventOrderCancelled reports an order removed from the book via Cancel.
+	EventOrderCancelled EventType = "order_cancelled"
+	// EventOrderRejected reports an order the engine refused to match or rest.
+	EventOrderRejected EventType = "order_rejected"
+)
+
+// Event is the channel-friendly representation of the callbacks registered
+// via OnTrade, OnOrderOpen, OnOrderFilled, OnOrderPartiallyFilled,
+// OnOrderCancelled and OnOrderRejected. Only the fields relevant to Type
+// are populated.
+type Event struct {
+	Type   EventType
+	Trade  *Trade
+	Order  *Order
+	Filled decimal.Decimal
+	Reason error
+}
+
+// subscribeBufferSize is the channel buffer used by Subscribe. Callbacks
+// run synchronously with matching, so a slow consumer that lets the
+// channel fill will block subsequent Match/Cancel calls.
+const subscribeBufferSize = 64
+
+// OnTrade registers a callback invoked for every trade the engine produces.
+func (e *Engine) OnTrade(fn func(Trade)) {
+	e.onTrade = append(e.onTrade, fn)
+}
+
+// OnOrderOpen registers a callback invoked whenever an order starts resting
+// in the book.
+func (e *Engine) OnOrderOpen(fn func(*Order)) {
+	e.onOrderOpen = append(e.onOrderOpen, fn)
+}
+
+// OnOrderFilled registers a callback invoked when an order's volume is
+// fully consumed by a trade.
+func (e *Engine) OnOrderFilled(fn func(*Order)) {
+	e.onOrderFilled = append(e.onOrderFilled, fn)
+}
+
+// OnOrderPartiallyFilled registers a callback invoked when a trade leaves
+// an order with volume still remaining. filled is the amount traded in
+// that step, not the order's cumulative fill.
+func (e *Engine) OnOrderPartiallyFilled(fn func(order *Order, filled decimal.Decimal)) {
+	e.onOrderPartiallyFilled = append(e.onOrderPartiallyFilled, fn)
+}
+
+// OnOrderCancelled registers a callback invoked when Cancel removes an
+// order from the book.
+func (e *Engine) OnOrderCancelled(fn func(*Order)) {
+	e.onOrderCancelled = append(e.onOrderCancelled, fn)
+}
+
+// OnOrderRejected registers a callback invoked when the engine refuses an
+// order instead of matching or resting it, e.g. a market order without
+// enough liquidity to fill.
+func (e *Engine) OnOrderRejected(fn func(order *Order, reason error)) {
+	e.onOrderRejected = append(e.onOrderRejected, fn)
+}
+
+// Subscribe returns a channel carrying every event the callbacks above
+// would otherwise report. It is built on those same callbacks, so
+// handlers registered before or after calling Subscribe all fire in
+// registration order.
+//
+// Callbacks, and therefore sends on this channel, happen synchronously
+// with matching; handlers must not call back into the Engine, and a
+// consumer that stops draining the channel will block it.
+func (e *Engine) Subscribe() <-chan Event {
+	ch := make(chan Event, subscribeBufferSize)
+
+	e.OnTrade(func(trd Trade) { ch <- Event{Type: EventTrade, Trade: &trd} })
+	e.OnOrderOpen(func(o *Order) { ch <- Event{Type: EventOrderOpen, Order: o} })
+	e.OnOrderFilled(func(o *Order) { ch <- Event{Type: EventOrderFilled, Order: o} })
+	e.OnOrderPartiallyFilled(func(o *Order, filled decimal.Decimal) {
+		ch <- Event{Type: EventOrderPartiallyFilled, Order: o, Filled: filled}
+	})
+	e.OnOrderCancelled(func(o *Order) { ch <- Event{Type: EventOrderCancelled, Order: o} })
+	e.OnOrderRejected(func(o *Order, reason error) {
+		ch <- Event{Type: EventOrderRejected, Order: o, Reason: reason}
+	})
+
+	return ch
+}
+
+func (e *Engine) fireTrade(trd Trade) {
+	for _, cb := range e.onTrade {
+		cb(trd)
+	}
+}
+
+func (e *Engine) fireOrderOpen(order *Order) {
+	for _, cb := range e.onOrderOpen {
+		cb(order)
+	}
+}
+
+// fireFill reports the result of a single execute() step on order: a
+// terminal OnOrderFilled if its volume reached zero and it has no Reserve
+// left to replenish from, otherwise an OnOrderPartiallyFilled for the
+// amount just traded. An iceberg whose visible slice is exhausted but
+// still has Reserve is still live in the book (replenishIceberg reopens
+// it), so it must not be reported as filled.
+func (e *Engine) fireFill(order *Order, amount decimal.Decimal) {
+	if order.Volume.IsZero() && order.Reserve.IsZero() {
+		for _, cb := range e.onOrderFilled {
+			cb(order)
+		}
+
+		return
+	}
+
+	for _, cb := range e.onOrderPartiallyFilled {
+		cb(order, amount)
+	}
+}
+
+func (e *Engine) fireOrderCancelled(order *Order) {
+	for _, cb := range e.onOrderCancelled {
+		cb(order)
+	}
+}
+
+func (e *Engine) fireOrderRejected(order *Order, reason error) {
+	for _, cb := range e.onOrderRejected {
+		cb(order, reason)
+	}
+}
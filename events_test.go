@@ -0,0 +1,77 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Events_TradeAndFill(t *testing.T) {
+	book := orderbook.New()
+
+	var trades []orderbook.Trade
+	var filled []*orderbook.Order
+	var partial []*orderbook.Order
+	var opened []*orderbook.Order
+
+	book.OnTrade(func(trd orderbook.Trade) { trades = append(trades, trd) })
+	book.OnOrderFilled(func(o *orderbook.Order) { filled = append(filled, o) })
+	book.OnOrderPartiallyFilled(func(o *orderbook.Order, _ decimal.Decimal) { partial = append(partial, o) })
+	book.OnOrderOpen(func(o *orderbook.Order) { opened = append(opened, o) })
+
+	mustMatch(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(2, "0.5", "6000.0", orderbook.SideBuy))
+
+	assert.Len(t, opened, 1)
+	assert.Equal(t, uint64(1), opened[0].ID)
+
+	assert.Len(t, trades, 1)
+	assert.Len(t, filled, 1)
+	assert.Equal(t, uint64(2), filled[0].ID)
+	assert.Len(t, partial, 1)
+	assert.Equal(t, uint64(1), partial[0].ID)
+}
+
+func TestEngine_Events_CancelAndReject(t *testing.T) {
+	book := orderbook.New()
+
+	var cancelled []*orderbook.Order
+	var rejected []error
+
+	book.OnOrderCancelled(func(o *orderbook.Order) { cancelled = append(cancelled, o) })
+	book.OnOrderRejected(func(_ *orderbook.Order, reason error) { rejected = append(rejected, reason) })
+
+	order := limitOrder(1, "1.0", "6000.0", orderbook.SideBuy)
+	mustMatch(t, book, order)
+	book.Cancel(order)
+
+	assert.Len(t, cancelled, 1)
+
+	mustMatch(t, book, marketOrder(2, "1.0", orderbook.SideSell))
+
+	assert.Len(t, rejected, 1)
+	assert.ErrorIs(t, rejected[0], orderbook.ErrInsufficientLiquidity)
+}
+
+func TestEngine_Subscribe(t *testing.T) {
+	book := orderbook.New()
+	events := book.Subscribe()
+
+	mustMatch(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(2, "1.0", "6000.0", orderbook.SideBuy))
+
+	var seen []orderbook.EventType
+	for i := 0; i < 4; i++ {
+		seen = append(seen, (<-events).Type)
+	}
+
+	assert.Equal(t, []orderbook.EventType{
+		orderbook.EventOrderOpen,
+		orderbook.EventTrade,
+		orderbook.EventOrderFilled,
+		orderbook.EventOrderFilled,
+	}, seen)
+}
@@ -0,0 +1,40 @@
+package orderbook
+
+import "github.com/shopspring/decimal"
+
+// FeeModel computes the maker and taker fee owed on a trade. taker
+// identifies which side of the trade was the aggressing order; the
+// opposite side is the maker. Returned fees are denominated in the
+// currency each side received (base for the buyer, quote for the
+// seller) and are deducted from Order.Received by the engine.
+type FeeModel interface {
+	Fee(trade *Trade, taker Side) (makerFee, takerFee decimal.Decimal)
+}
+
+// ZeroFeeModel charges no fees, preserving the engine's default behavior.
+type ZeroFeeModel struct{}
+
+// Fee implements FeeModel.
+func (ZeroFeeModel) Fee(*Trade, Side) (decimal.Decimal, decimal.Decimal) {
+	return decimal.Zero, decimal.Zero
+}
+
+// FlatFeeModel charges a flat percentage rate of the amount each side
+// received, with independent rates for makers and takers.
+type FlatFeeModel struct {
+	MakerRate decimal.Decimal
+	TakerRate decimal.Decimal
+}
+
+// Fee implements FeeModel.
+func (m FlatFeeModel) Fee(trade *Trade, taker Side) (decimal.Decimal, decimal.Decimal) {
+	buyReceived := trade.Amount
+	sellReceived := trade.Amount.Mul(trade.Price)
+
+	takerReceived, makerReceived := sellReceived, buyReceived
+	if taker == SideBuy {
+		takerReceived, makerReceived = buyReceived, sellReceived
+	}
+
+	return makerReceived.Mul(m.MakerRate), takerReceived.Mul(m.TakerRate)
+}
@@ -0,0 +1,88 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type feeTestCase struct {
+	Model  orderbook.FeeModel
+	Orders []*orderbook.Order
+	Trades []orderbook.Trade
+}
+
+func (tCase *feeTestCase) OrderLimit(side orderbook.Side, amount string, price string) *feeTestCase {
+	order := limitOrder(uint64(len(tCase.Orders)+1), amount, price, side)
+
+	tCase.Orders = append(tCase.Orders, order)
+
+	return tCase
+}
+
+func (tCase *feeTestCase) Trade(taker orderbook.Side, amount, price, makerFee, takerFee string) *feeTestCase {
+	tCase.Trades = append(tCase.Trades, orderbook.Trade{
+		Taker:    taker,
+		Amount:   decimal.RequireFromString(amount),
+		Price:    decimal.RequireFromString(price),
+		MakerFee: decimal.RequireFromString(makerFee),
+		TakerFee: decimal.RequireFromString(takerFee),
+	})
+
+	return tCase
+}
+
+func (tCase *feeTestCase) Assert(t *testing.T) {
+	test := assert.New(t)
+
+	book := orderbook.New(orderbook.WithFeeModel(tCase.Model))
+
+	var trades []orderbook.Trade
+
+	for _, obj := range tCase.Orders {
+		traded, _, err := book.Match(obj)
+		test.NoError(err)
+
+		trades = append(trades, traded...)
+	}
+
+	test.Len(trades, len(tCase.Trades), "trades number mismatch")
+
+	for i := 0; i < len(trades); i++ {
+		test.Equal(tCase.Trades[i].Taker, trades[i].Taker)
+		test.True(tCase.Trades[i].MakerFee.Equal(trades[i].MakerFee), "maker fee: want %s got %s", tCase.Trades[i].MakerFee, trades[i].MakerFee)
+		test.True(tCase.Trades[i].TakerFee.Equal(trades[i].TakerFee), "taker fee: want %s got %s", tCase.Trades[i].TakerFee, trades[i].TakerFee)
+	}
+}
+
+func TestEngine_Fee_Zero_ByDefault(t *testing.T) {
+	var testcase feeTestCase
+
+	testcase.Model = orderbook.ZeroFeeModel{}
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		OrderLimit(orderbook.SideBuy, "1.0", "6000.0").
+		Trade(orderbook.SideBuy, "1.0", "6000.0", "0", "0").
+		Assert(t)
+}
+
+func TestEngine_Fee_FlatModel_ChargesMakerAndTaker(t *testing.T) {
+	var testcase feeTestCase
+
+	testcase.Model = orderbook.FlatFeeModel{
+		MakerRate: decimal.RequireFromString("0.001"),
+		TakerRate: decimal.RequireFromString("0.002"),
+	}
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		OrderLimit(orderbook.SideBuy, "1.0", "6000.0").
+		// Maker is the resting sell, receives quote (6000); taker is the
+		// incoming buy, receives base (1.0).
+		Trade(orderbook.SideBuy, "1.0", "6000.0", "6", "0.002").
+		Assert(t)
+}
@@ -0,0 +1,34 @@
+package orderbook
+
+// icebergSeqBase is well above any ID this engine's callers are expected
+// to assign, so a replenished iceberg slice's Seq always outranks (in
+// time, i.e. sorts after) any ID-keyed peer at the same price.
+const icebergSeqBase = 1 << 62
+
+// splitIceberg caps a newly-resting order's visible Volume at
+// DisplayVolume, moving the remainder into Reserve. Orders with no
+// DisplayVolume, or one at least as large as Volume, are left untouched.
+func splitIceberg(order *Order) {
+	if order.DisplayVolume.IsPositive() && order.DisplayVolume.LessThan(order.Volume) {
+		order.Reserve = order.Volume.Sub(order.DisplayVolume)
+		order.Volume = order.DisplayVolume
+	}
+}
+
+// replenishIceberg refills order's visible Volume from Reserve, up to
+// DisplayVolume, and assigns it a new Seq so it loses time priority to
+// same-price peers that were already resting.
+func (e *Engine) replenishIceberg(order *Order) {
+	refill := order.DisplayVolume
+	if order.Reserve.LessThan(refill) {
+		refill = order.Reserve
+	}
+
+	order.Volume = refill
+	order.Reserve = order.Reserve.Sub(refill)
+
+	e.icebergSeq++
+	order.Seq = icebergSeqBase + e.icebergSeq
+
+	e.fireOrderOpen(order)
+}
@@ -0,0 +1,108 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func icebergOrder(id uint64, amount, display, price string, side orderbook.Side) *orderbook.Order {
+	order := limitOrder(id, amount, price, side)
+	order.DisplayVolume = decimal.RequireFromString(display)
+
+	return order
+}
+
+func TestEngine_Iceberg_MarketSweepReplenishes(t *testing.T) {
+	book := orderbook.New()
+
+	mustMatch(t, book, icebergOrder(1, "1.0", "0.2", "6000.0", orderbook.SideSell))
+
+	snap := book.Snapshot(1)
+	assert.True(t, snap.Asks[0].Volume.Equal(decimal.RequireFromString("0.2")), "only the display slice should be visible: got %s", snap.Asks[0].Volume)
+
+	sweep := marketOrder(2, "1.0", orderbook.SideBuy)
+	sweep.Locked = decimal.RequireFromString("6000.0")
+
+	trades, _, err := book.Match(sweep)
+	assert.NoError(t, err)
+	assert.Len(t, trades, 5)
+
+	var filled decimal.Decimal
+	for _, trd := range trades {
+		filled = filled.Add(trd.Amount)
+		assert.True(t, trd.Amount.Equal(decimal.RequireFromString("0.2")))
+	}
+
+	assert.True(t, filled.Equal(decimal.RequireFromString("1.0")))
+
+	snap = book.Snapshot(1)
+	assert.Empty(t, snap.Asks, "iceberg should be fully depleted")
+}
+
+func TestEngine_Iceberg_ReplenishFiresPartialNotFilled(t *testing.T) {
+	book := orderbook.New()
+
+	var filled []*orderbook.Order
+	var partial []*orderbook.Order
+	var opened []*orderbook.Order
+
+	book.OnOrderFilled(func(o *orderbook.Order) { filled = append(filled, o) })
+	book.OnOrderPartiallyFilled(func(o *orderbook.Order, _ decimal.Decimal) { partial = append(partial, o) })
+	book.OnOrderOpen(func(o *orderbook.Order) { opened = append(opened, o) })
+
+	mustMatch(t, book, icebergOrder(1, "1.0", "0.2", "6000.0", orderbook.SideSell))
+	opened = nil // drop the resting-open event, we only care about the replenish below
+
+	trades, _, err := book.Match(limitOrder(2, "0.2", "6000.0", orderbook.SideBuy))
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+
+	// The taker (id 2) is a plain order fully consumed, so it legitimately
+	// fires OnOrderFilled; only the iceberg maker (id 1) must not.
+	assert.Len(t, filled, 1)
+	assert.Equal(t, uint64(2), filled[0].ID)
+	assert.Len(t, partial, 1, "iceberg maker should report a partial fill instead of OnOrderFilled")
+	assert.Equal(t, uint64(1), partial[0].ID)
+	assert.Len(t, opened, 1, "replenish should reopen the order")
+	assert.Equal(t, uint64(1), opened[0].ID)
+}
+
+func TestEngine_Iceberg_PartialDisplayFillDoesNotRefill(t *testing.T) {
+	book := orderbook.New()
+
+	iceberg := icebergOrder(1, "1.0", "0.5", "6000.0", orderbook.SideSell)
+	mustMatch(t, book, iceberg)
+
+	trades, _, err := book.Match(limitOrder(2, "0.2", "6000.0", orderbook.SideBuy))
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+
+	assert.True(t, iceberg.Volume.Equal(decimal.RequireFromString("0.3")), "display slice should only shrink, not refill")
+	assert.True(t, iceberg.Reserve.Equal(decimal.RequireFromString("0.5")))
+	assert.Equal(t, uint64(0), iceberg.Seq)
+}
+
+func TestEngine_Iceberg_RefillLosesTimePriority(t *testing.T) {
+	book := orderbook.New()
+
+	iceberg := icebergOrder(1, "0.4", "0.2", "6000.0", orderbook.SideSell)
+	mustMatch(t, book, iceberg)
+	mustMatch(t, book, limitOrder(2, "0.2", "6000.0", orderbook.SideSell))
+
+	trades, _, err := book.Match(limitOrder(3, "0.2", "6000.0", orderbook.SideBuy))
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+	assert.Equal(t, uint64(1), trades[0].Sell.ID, "iceberg rests first and trades first")
+
+	assert.True(t, iceberg.Volume.Equal(decimal.RequireFromString("0.2")), "iceberg should have refilled")
+	assert.NotZero(t, iceberg.Seq)
+
+	trades, _, err = book.Match(limitOrder(4, "0.2", "6000.0", orderbook.SideBuy))
+	assert.NoError(t, err)
+	assert.Len(t, trades, 1)
+	assert.Equal(t, uint64(2), trades[0].Sell.ID, "plain order now trades ahead of the replenished iceberg")
+}
@@ -1,6 +1,8 @@
 package orderbook
 
 import (
+	"time"
+
 	"github.com/google/btree"
 
 	"github.com/shopspring/decimal"
@@ -25,6 +27,12 @@ const (
 	KindMarket Kind = "market"
 	// KindLimit is representation of Limit order.
 	KindLimit Kind = "limit"
+	// KindStop is a parked order that activates into a KindMarket order
+	// once TriggerPrice is crossed by the tape.
+	KindStop Kind = "stop"
+	// KindStopLimit is a parked order that activates into a KindLimit
+	// order, at Price, once TriggerPrice is crossed by the tape.
+	KindStopLimit Kind = "stop_limit"
 )
 
 type Order struct {
@@ -35,6 +43,30 @@ type Order struct {
 	Volume   decimal.Decimal `json:"volume"`
 	Locked   decimal.Decimal `json:"locked"`
 	Received decimal.Decimal `json:"received"`
+
+	// TIF is the order's time-in-force policy. The zero value is TIFGTC,
+	// preserving the engine's default resting behavior.
+	TIF TIF `json:"tif,omitempty"`
+	// ExpiresAt is the deadline for a TIFGTD order. Ignored otherwise.
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+
+	// DisplayVolume caps how much of an iceberg order is visible and
+	// matchable while resting. Zero means fully visible (current
+	// behavior). The remainder is tracked in Reserve once the order
+	// rests in the book.
+	DisplayVolume decimal.Decimal `json:"display_volume,omitempty"`
+	// Reserve is the hidden remaining volume of an iceberg order once it
+	// rests in the book; it does not participate in matching directly.
+	Reserve decimal.Decimal `json:"reserve,omitempty"`
+	// Seq, once set, overrides ID as the time-priority tiebreaker at a
+	// given price level. The engine assigns it when an iceberg order's
+	// visible slice is replenished from Reserve, so the refill loses its
+	// original time priority to same-price peers.
+	Seq uint64 `json:"seq,omitempty"`
+
+	// TriggerPrice is the tape price at which a KindStop or
+	// KindStopLimit order activates. Ignored otherwise.
+	TriggerPrice decimal.Decimal `json:"trigger_price,omitempty"`
 }
 
 type Trade struct {
@@ -42,6 +74,14 @@ type Trade struct {
 	Sell   Order           `json:"sell"`
 	Amount decimal.Decimal `json:"amount"`
 	Price  decimal.Decimal `json:"price"`
+
+	// Taker is the side of the aggressing order; the other side is the maker.
+	Taker Side `json:"taker"`
+	// MakerFee and TakerFee are denominated in the currency each side
+	// received (base for the buyer, quote for the seller) and have
+	// already been deducted from the corresponding Order.Received.
+	MakerFee decimal.Decimal `json:"maker_fee"`
+	TakerFee decimal.Decimal `json:"taker_fee"`
 }
 
 func NewTrade(buy, sell *Order, amount, price decimal.Decimal) *Trade {
@@ -65,7 +105,7 @@ func CalculateLocked(amount, price decimal.Decimal, side Side) decimal.Decimal {
 	}
 }
 
-// Less compares two orders by price & ID, respects order Side.
+// Less compares two orders by price & time priority, respects order Side.
 // Used in order-book for sorting btree of orders.
 func (o *Order) Less(other btree.Item) bool {
 	operand := other.(*Order)
@@ -78,7 +118,7 @@ func (o *Order) Less(other btree.Item) bool {
 			return true
 		}
 
-		return o.ID < operand.ID
+		return priorityKey(o) < priorityKey(operand)
 	case SideSell:
 		if o.Price.LessThan(operand.Price) {
 			return true
@@ -86,8 +126,19 @@ func (o *Order) Less(other btree.Item) bool {
 			return false
 		}
 
-		return o.ID < operand.ID
+		return priorityKey(o) < priorityKey(operand)
 	}
 
 	return false
 }
+
+// priorityKey returns the value used to order same-price orders by time:
+// an order's ID, unless Seq has been set (an iceberg refill), in which
+// case Seq takes precedence.
+func priorityKey(o *Order) uint64 {
+	if o.Seq != 0 {
+		return o.Seq
+	}
+
+	return o.ID
+}
@@ -0,0 +1,79 @@
+package orderbook
+
+import (
+	"github.com/google/btree"
+	"github.com/shopspring/decimal"
+)
+
+// Level is one aggregated price level in a Snapshot.
+type Level struct {
+	Price      decimal.Decimal `json:"price"`
+	Volume     decimal.Decimal `json:"volume"`
+	OrderCount int             `json:"order_count"`
+}
+
+// Snapshot is a depth-aggregated view of the book as of Sequence.
+type Snapshot struct {
+	Bids     []Level `json:"bids"`
+	Asks     []Level `json:"asks"`
+	Sequence uint64  `json:"sequence"`
+}
+
+// Snapshot aggregates the book into up to levels price levels per side,
+// best price first. Sequence is a monotonic counter bumped on every
+// state-changing operation (Match, Cancel, expiry removal), letting
+// consumers detect gaps between snapshots.
+func (e *Engine) Snapshot(levels int) Snapshot {
+	return Snapshot{
+		Bids:     aggregateLevels(e.buys, levels),
+		Asks:     aggregateLevels(e.sells, levels),
+		Sequence: e.sequence,
+	}
+}
+
+// L3Snapshot returns a deep copy of every resting order on each side, in
+// priority order, for audits and replay/checkpointing.
+func (e *Engine) L3Snapshot() (bids, asks []*Order) {
+	return deepCopyOrders(e.buys), deepCopyOrders(e.sells)
+}
+
+func aggregateLevels(tree *btree.BTree, levels int) []Level {
+	result := make([]Level, 0, levels)
+
+	tree.Ascend(func(i btree.Item) bool {
+		order := i.(*Order)
+
+		if len(result) > 0 {
+			last := &result[len(result)-1]
+			if last.Price.Equal(order.Price) {
+				last.Volume = last.Volume.Add(order.Volume)
+				last.OrderCount++
+
+				return true
+			}
+		}
+
+		if len(result) == levels {
+			return false
+		}
+
+		result = append(result, Level{Price: order.Price, Volume: order.Volume, OrderCount: 1})
+
+		return true
+	})
+
+	return result
+}
+
+func deepCopyOrders(tree *btree.BTree) []*Order {
+	orders := make([]*Order, 0, tree.Len())
+
+	tree.Ascend(func(i btree.Item) bool {
+		order := *i.(*Order)
+		orders = append(orders, &order)
+
+		return true
+	})
+
+	return orders
+}
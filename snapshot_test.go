@@ -0,0 +1,60 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEngine_Snapshot_AggregatesLevels(t *testing.T) {
+	book := orderbook.New()
+
+	mustMatch(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(2, "0.5", "6000.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(3, "1.0", "6100.0", orderbook.SideSell))
+	mustMatch(t, book, limitOrder(4, "1.0", "5900.0", orderbook.SideBuy))
+
+	snap := book.Snapshot(1)
+
+	assert.Len(t, snap.Asks, 1)
+	assert.Equal(t, 2, snap.Asks[0].OrderCount)
+	assert.True(t, snap.Asks[0].Price.Equal(decimal.RequireFromString("6000.0")))
+	assert.True(t, snap.Asks[0].Volume.Equal(decimal.RequireFromString("1.5")), "want 1.5, got %s", snap.Asks[0].Volume)
+
+	assert.Len(t, snap.Bids, 1)
+	assert.Equal(t, 1, snap.Bids[0].OrderCount)
+}
+
+func TestEngine_Snapshot_SequenceAdvances(t *testing.T) {
+	book := orderbook.New()
+
+	before := book.Snapshot(1).Sequence
+
+	mustMatch(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideBuy))
+
+	after := book.Snapshot(1).Sequence
+	assert.Greater(t, after, before)
+
+	book.Cancel(limitOrder(1, "1.0", "6000.0", orderbook.SideBuy))
+
+	afterCancel := book.Snapshot(1).Sequence
+	assert.Greater(t, afterCancel, after)
+}
+
+func TestEngine_L3Snapshot_DeepCopiesOrders(t *testing.T) {
+	book := orderbook.New()
+
+	mustMatch(t, book, limitOrder(1, "1.0", "6000.0", orderbook.SideBuy))
+
+	bids, asks := book.L3Snapshot()
+	assert.Len(t, bids, 1)
+	assert.Empty(t, asks)
+
+	bids[0].Volume = bids[0].Volume.Add(bids[0].Volume)
+
+	bidsAgain, _ := book.L3Snapshot()
+	assert.False(t, bidsAgain[0].Volume.Equal(bids[0].Volume), "mutating a snapshot order must not affect the book")
+}
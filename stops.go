@@ -0,0 +1,122 @@
+package orderbook
+
+import (
+	"github.com/google/btree"
+	"github.com/shopspring/decimal"
+)
+
+// maxStopCascade bounds how many rounds of stop activation a single Match
+// call will chase, guarding against one stop's fill immediately triggering
+// another (and so on) without end.
+const maxStopCascade = 32
+
+// stopItem orders parked KindStop/KindStopLimit orders by TriggerPrice
+// then time, independent of Order.Less, which sorts the live book by
+// limit Price.
+type stopItem struct {
+	*Order
+}
+
+func (s stopItem) Less(other btree.Item) bool {
+	o := other.(stopItem)
+
+	if s.TriggerPrice.LessThan(o.TriggerPrice) {
+		return true
+	} else if s.TriggerPrice.GreaterThan(o.TriggerPrice) {
+		return false
+	}
+
+	return priorityKey(s.Order) < priorityKey(o.Order)
+}
+
+// parkStop files a KindStop/KindStopLimit order away until its
+// TriggerPrice is crossed by the tape.
+func (e *Engine) parkStop(order *Order) {
+	switch order.Side {
+	case SideBuy:
+		e.stopsBuy.ReplaceOrInsert(stopItem{order})
+	case SideSell:
+		e.stopsSell.ReplaceOrInsert(stopItem{order})
+	}
+}
+
+// activateStop converts a parked order into the live order it represents:
+// KindStop becomes KindMarket, KindStopLimit becomes KindLimit at its
+// existing Price.
+func activateStop(order *Order) *Order {
+	switch order.Kind {
+	case KindStop:
+		order.Kind = KindMarket
+	case KindStopLimit:
+		order.Kind = KindLimit
+	}
+
+	return order
+}
+
+// processTriggeredStops scans both parked-stop trees against the latest
+// tape price, activating and matching any that have crossed their
+// trigger, and folds the resulting trades into trades. It repeats until
+// no further stop is triggered (a fill can itself move the tape and
+// trigger the next one) or maxStopCascade rounds have run.
+func (e *Engine) processTriggeredStops(trades []Trade) []Trade {
+	if len(trades) == 0 {
+		return trades
+	}
+
+	for round := 0; round < maxStopCascade; round++ {
+		// Asks (parked sell-side stops) trigger once the tape trades at
+		// or above their TriggerPrice; bids (parked buy-side stops)
+		// trigger once it trades at or below theirs.
+		asks := e.collectTriggeredStops(e.stopsSell, func(trigger decimal.Decimal) bool {
+			return e.lastTradePrice.GreaterThanOrEqual(trigger)
+		})
+		bids := e.collectTriggeredStops(e.stopsBuy, func(trigger decimal.Decimal) bool {
+			return e.lastTradePrice.LessThanOrEqual(trigger)
+		})
+
+		triggered := append(asks, bids...)
+		if len(triggered) == 0 {
+			break
+		}
+
+		for _, order := range triggered {
+			activated := activateStop(order)
+
+			var fills []Trade
+
+			switch activated.Kind {
+			case KindMarket:
+				fills, _ = e.matchMarket(activated)
+			case KindLimit:
+				fills, _ = e.matchLimit(activated)
+			}
+
+			trades = append(trades, fills...)
+		}
+	}
+
+	return trades
+}
+
+// collectTriggeredStops removes and returns every order parked in tree
+// for which trigger reports true.
+func (e *Engine) collectTriggeredStops(tree *btree.BTree, trigger func(decimal.Decimal) bool) []*Order {
+	var triggered []*Order
+
+	tree.Ascend(func(i btree.Item) bool {
+		order := i.(stopItem).Order
+
+		if trigger(order.TriggerPrice) {
+			triggered = append(triggered, order)
+		}
+
+		return true
+	})
+
+	for _, order := range triggered {
+		tree.Delete(stopItem{order})
+	}
+
+	return triggered
+}
@@ -0,0 +1,87 @@
+package orderbook_test
+
+import (
+	"testing"
+
+	"github.com/shal/orderbook"
+
+	"github.com/shopspring/decimal"
+)
+
+// OrderStop parks a KindStop order: a market order that only activates
+// once the tape crosses triggerPrice.
+func (tCase *testCase) OrderStop(side orderbook.Side, amount, locked, triggerPrice string) *testCase {
+	order := marketOrder(uint64(len(tCase.Orders)+1), amount, side)
+	order.Kind = orderbook.KindStop
+	order.Locked = decimal.RequireFromString(locked)
+	order.TriggerPrice = decimal.RequireFromString(triggerPrice)
+
+	tCase.Orders = append(tCase.Orders, order)
+
+	return tCase
+}
+
+// OrderStopLimit parks a KindStopLimit order: a limit order at price that
+// only activates once the tape crosses triggerPrice.
+func (tCase *testCase) OrderStopLimit(side orderbook.Side, amount, price, triggerPrice string) *testCase {
+	order := limitOrder(uint64(len(tCase.Orders)+1), amount, price, side)
+	order.Kind = orderbook.KindStopLimit
+	order.TriggerPrice = decimal.RequireFromString(triggerPrice)
+
+	tCase.Orders = append(tCase.Orders, order)
+
+	return tCase
+}
+
+// Triggered records a trade expected from a parked stop activating once
+// the tape crosses its TriggerPrice.
+func (tCase *testCase) Triggered(buyID, sellID uint64, amount, price string) *testCase {
+	return tCase.Trade(buyID, sellID, amount, price)
+}
+
+func TestEngine_Stop_Sell_TriggersOnTapeAtOrAboveTrigger(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderStop(orderbook.SideSell, "0.5", "0.5", "6000.0").
+		OrderLimit(orderbook.SideBuy, "1.5", "6000.0").
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		Trade(2, 3, "1.0", "6000.0").
+		Triggered(2, 1, "0.5", "6000.0").
+		Assert(t)
+}
+
+func TestEngine_Stop_Buy_TriggersOnTapeAtOrBelowTrigger(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderStop(orderbook.SideBuy, "0.5", "3000.0", "6000.0").
+		OrderLimit(orderbook.SideSell, "1.5", "6000.0").
+		OrderLimit(orderbook.SideBuy, "1.0", "6000.0").
+		Trade(3, 2, "1.0", "6000.0").
+		Triggered(1, 2, "0.5", "6000.0").
+		Assert(t)
+}
+
+func TestEngine_Stop_NotTriggeredWhenTapeDoesNotCross(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderStop(orderbook.SideSell, "0.5", "0.5", "7000.0").
+		OrderLimit(orderbook.SideBuy, "1.5", "6000.0").
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		Trade(2, 3, "1.0", "6000.0").
+		Assert(t)
+}
+
+func TestEngine_StopLimit_ActivatesAsLimitAtItsOwnPrice(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderStopLimit(orderbook.SideSell, "0.5", "5900.0", "6000.0").
+		OrderLimit(orderbook.SideBuy, "1.5", "6000.0").
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		Trade(2, 3, "1.0", "6000.0").
+		Triggered(2, 1, "0.5", "5900.0").
+		Assert(t)
+}
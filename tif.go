@@ -0,0 +1,104 @@
+package orderbook
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/btree"
+	"github.com/shopspring/decimal"
+)
+
+// TIF is a limit order's time-in-force policy.
+type TIF string
+
+const (
+	// TIFGTC is the zero value: good-till-cancelled, the engine's default
+	// behavior of resting any unfilled remainder in the book.
+	TIFGTC TIF = ""
+	// TIFIOC is immediate-or-cancel: match what is available, then reject
+	// any remainder instead of resting it.
+	TIFIOC TIF = "ioc"
+	// TIFFOK is fill-or-kill: either the order fills completely against
+	// the resting book in one shot, or it is rejected with no trades.
+	TIFFOK TIF = "fok"
+	// TIFGTD is good-till-date: behaves like TIFGTC until ExpiresAt, after
+	// which it is lazily skipped and removed from the book.
+	TIFGTD TIF = "gtd"
+	// TIFPostOnly rejects the order instead of resting it if it would
+	// cross the book on arrival.
+	TIFPostOnly TIF = "post_only"
+)
+
+// ErrPostOnlyCross is passed to OnOrderRejected when a TIFPostOnly order
+// would have crossed the book on arrival.
+var ErrPostOnlyCross = errors.New("orderbook: post-only order would cross")
+
+// ErrFillOrKill is passed to OnOrderRejected when a TIFFOK order cannot be
+// filled completely against the resting book.
+var ErrFillOrKill = errors.New("orderbook: fill-or-kill order could not be fully filled")
+
+// ErrImmediateOrCancel is passed to OnOrderRejected when a TIFIOC order has
+// volume left over after matching what it could.
+var ErrImmediateOrCancel = errors.New("orderbook: immediate-or-cancel order left unfilled volume")
+
+// expired reports whether a resting TIFGTD order is past its deadline
+// according to the engine's clock.
+func (e *Engine) expired(order *Order) bool {
+	return order.TIF == TIFGTD && !order.ExpiresAt.IsZero() && !e.now().Before(order.ExpiresAt)
+}
+
+// crosses reports whether order would immediately match the best resting
+// order on side, without performing any matching.
+func (e *Engine) crosses(order *Order, side *btree.BTree) bool {
+	if side.Len() == 0 {
+		return false
+	}
+
+	other := side.Min().(*Order)
+	if e.expired(other) {
+		return false
+	}
+
+	return ordersMatch(order, other)
+}
+
+// canFullyFillLimit reports whether order could be completely matched
+// against side at its limit price, without performing any matching. It is
+// estimateMarket's counterpart for limit orders: instead of a market
+// order's unconditional eligibility, each resting order must still satisfy
+// ordersMatch against order's limit price.
+func (e *Engine) canFullyFillLimit(order *Order, side *btree.BTree) bool {
+	var volume decimal.Decimal
+
+	side.Ascend(func(i btree.Item) bool {
+		other := i.(*Order)
+
+		if e.expired(other) {
+			return true
+		}
+
+		if !ordersMatch(order, other) {
+			return false
+		}
+
+		// An iceberg's Reserve is hidden from snapshots but still real
+		// liquidity the engine can sweep through via replenishment.
+		volume = volume.Add(other.Volume).Add(other.Reserve)
+
+		return volume.LessThan(order.Volume)
+	})
+
+	return volume.GreaterThanOrEqual(order.Volume)
+}
+
+// WithClock overrides the clock the engine uses to decide whether a TIFGTD
+// order has expired. Defaults to time.Now; mainly useful in tests.
+func WithClock(clock func() time.Time) Option {
+	return func(e *Engine) {
+		e.clock = clock
+	}
+}
+
+func (e *Engine) now() time.Time {
+	return e.clock()
+}
@@ -0,0 +1,146 @@
+package orderbook_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shal/orderbook"
+)
+
+func limitOrderTIF(id uint64, amount, price string, side orderbook.Side, tif orderbook.TIF) *orderbook.Order {
+	order := limitOrder(id, amount, price, side)
+	order.TIF = tif
+
+	return order
+}
+
+func (tCase *testCase) OrderLimitTIF(side orderbook.Side, amount, price string, tif orderbook.TIF) *testCase {
+	order := limitOrderTIF(uint64(len(tCase.Orders)+1), amount, price, side, tif)
+
+	tCase.Orders = append(tCase.Orders, order)
+
+	return tCase
+}
+
+func (tCase *testCase) OrderLimitGTD(side orderbook.Side, amount, price string, expiresAt time.Time) *testCase {
+	order := limitOrderTIF(uint64(len(tCase.Orders)+1), amount, price, side, orderbook.TIFGTD)
+	order.ExpiresAt = expiresAt
+
+	tCase.Orders = append(tCase.Orders, order)
+
+	return tCase
+}
+
+// RejectLimit records an expected reject for a rejected limit order, as
+// driven by TIF semantics rather than market-order liquidity checks.
+func (tCase *testCase) RejectLimit(id uint64, side orderbook.Side, price string) *testCase {
+	reject := limitOrder(id, "0.0", price, side)
+
+	tCase.Rejects = append(tCase.Rejects, reject)
+
+	return tCase
+}
+
+func TestEngine_TIF_IOC_PartialThenReject(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "0.5", "6000.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6000.0", orderbook.TIFIOC).
+		Trade(2, 1, "0.5", "6000.0").
+		RejectLimit(2, orderbook.SideBuy, "6000.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_IOC_FullFill_NoReject(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6000.0", orderbook.TIFIOC).
+		Trade(2, 1, "1.0", "6000.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_FOK_RejectsWithoutTrading(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "0.5", "6000.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6000.0", orderbook.TIFFOK).
+		RejectLimit(2, orderbook.SideBuy, "6000.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_FOK_FullFillAcrossLevels(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "0.5", "6000.0").
+		OrderLimit(orderbook.SideSell, "0.5", "6100.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6100.0", orderbook.TIFFOK).
+		Trade(3, 1, "0.5", "6000.0").
+		Trade(3, 2, "0.5", "6100.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_PostOnly_RejectsWhenCrossing(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6000.0", orderbook.TIFPostOnly).
+		RejectLimit(2, orderbook.SideBuy, "6000.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_PostOnly_RestsWhenNotCrossing(t *testing.T) {
+	var testcase testCase
+
+	testcase.
+		OrderLimit(orderbook.SideSell, "1.0", "6100.0").
+		OrderLimitTIF(orderbook.SideBuy, "1.0", "6000.0", orderbook.TIFPostOnly).
+		Assert(t)
+}
+
+func TestEngine_TIF_GTD_ExpiresAndIsSkipped(t *testing.T) {
+	var testcase testCase
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testcase.Clock = func() time.Time { return start.Add(time.Hour) }
+
+	testcase.
+		OrderLimitGTD(orderbook.SideSell, "1.0", "6000.0", start.Add(time.Minute)).
+		OrderLimit(orderbook.SideBuy, "1.0", "6000.0").
+		Assert(t)
+}
+
+func TestEngine_TIF_GTD_MarketOrderIgnoresExpiredLiquidity(t *testing.T) {
+	var testcase testCase
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testcase.Clock = func() time.Time { return start.Add(time.Hour) }
+
+	testcase.
+		OrderLimitGTD(orderbook.SideSell, "1.0", "6000.0", start.Add(time.Minute)).
+		OrderLimit(orderbook.SideSell, "1.0", "6000.0").
+		OrderMarket(orderbook.SideBuy, "1.5", "10000.0").
+		Reject(3, orderbook.SideBuy).
+		Assert(t)
+}
+
+func TestEngine_TIF_GTD_MatchesBeforeExpiry(t *testing.T) {
+	var testcase testCase
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	testcase.Clock = func() time.Time { return start }
+
+	testcase.
+		OrderLimitGTD(orderbook.SideSell, "1.0", "6000.0", start.Add(time.Hour)).
+		OrderLimit(orderbook.SideBuy, "1.0", "6000.0").
+		Trade(2, 1, "1.0", "6000.0").
+		Assert(t)
+}